@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"hash/fnv"
 	"slices"
 	"unsafe"
@@ -18,9 +19,10 @@ const GroupByAll = "..."
 var DefaultNotificationSettingsGroupBy = []string{FolderTitleLabel, model.AlertNameLabel}
 
 type ListNotificationSettingsQuery struct {
-	OrgID            int64
-	ReceiverName     string
-	TimeIntervalName string
+	OrgID                  int64
+	ReceiverName           string
+	TimeIntervalName       string
+	ActiveTimeIntervalName string
 }
 
 // NotificationSettings represents the settings for sending notifications for a single AlertRule. It is used to
@@ -28,11 +30,12 @@ type ListNotificationSettingsQuery struct {
 type NotificationSettings struct {
 	Receiver string `json:"receiver"`
 
-	GroupBy           []string        `json:"group_by,omitempty"`
-	GroupWait         *model.Duration `json:"group_wait,omitempty"`
-	GroupInterval     *model.Duration `json:"group_interval,omitempty"`
-	RepeatInterval    *model.Duration `json:"repeat_interval,omitempty"`
-	MuteTimeIntervals []string        `json:"mute_time_intervals,omitempty"`
+	GroupBy             []string        `json:"group_by,omitempty"`
+	GroupWait           *model.Duration `json:"group_wait,omitempty"`
+	GroupInterval       *model.Duration `json:"group_interval,omitempty"`
+	RepeatInterval      *model.Duration `json:"repeat_interval,omitempty"`
+	MuteTimeIntervals   []string        `json:"mute_time_intervals,omitempty"`
+	ActiveTimeIntervals []string        `json:"active_time_intervals,omitempty"`
 }
 
 func (s *NotificationSettings) GetUID() string {
@@ -77,10 +80,12 @@ func (s *NotificationSettings) NormalizedGroupBy() []string {
 
 // Validate checks if the NotificationSettings object is valid.
 // It returns an error if any of the validation checks fail.
-// The receiver must be specified.
+// The receiver must be specified, unless parent is non-nil and specifies one, in which case it is inherited.
 // GroupWait, GroupInterval, RepeatInterval must be positive durations.
-func (s *NotificationSettings) Validate() error {
-	if s.Receiver == "" {
+// MuteTimeIntervals and ActiveTimeIntervals must reference time intervals that exist in the org; availableTimeIntervals
+// is the list of names currently defined for the org the rule belongs to.
+func (s *NotificationSettings) Validate(parent *NotificationSettings, availableTimeIntervals []string) error {
+	if s.Receiver == "" && (parent == nil || parent.Receiver == "") {
 		return errors.New("receiver must be specified")
 	}
 	if s.GroupWait != nil && *s.GroupWait < 0 {
@@ -92,21 +97,70 @@ func (s *NotificationSettings) Validate() error {
 	if s.RepeatInterval != nil && *s.RepeatInterval <= 0 {
 		return errors.New("repeat interval must be greater than zero")
 	}
+	available := make(map[string]struct{}, len(availableTimeIntervals))
+	for _, name := range availableTimeIntervals {
+		available[name] = struct{}{}
+	}
+	for _, name := range s.MuteTimeIntervals {
+		if _, ok := available[name]; !ok {
+			return fmt.Errorf("mute time interval %q does not exist", name)
+		}
+	}
+	for _, name := range s.ActiveTimeIntervals {
+		if _, ok := available[name]; !ok {
+			return fmt.Errorf("active time interval %q does not exist", name)
+		}
+	}
 	return nil
 }
 
+// Resolve returns a copy of s with every unset field (nil GroupWait/GroupInterval/RepeatInterval, empty
+// GroupBy/MuteTimeIntervals/ActiveTimeIntervals, empty Receiver) filled in from parent, mirroring Alertmanager's route
+// inheritance semantics. Fields that are set on s always take precedence over parent. If parent is nil,
+// s is returned unchanged.
+func (s NotificationSettings) Resolve(parent *NotificationSettings) NotificationSettings {
+	if parent == nil {
+		return s
+	}
+	resolved := s
+	if resolved.Receiver == "" {
+		resolved.Receiver = parent.Receiver
+	}
+	if len(resolved.GroupBy) == 0 {
+		resolved.GroupBy = parent.GroupBy
+	}
+	if resolved.GroupWait == nil {
+		resolved.GroupWait = parent.GroupWait
+	}
+	if resolved.GroupInterval == nil {
+		resolved.GroupInterval = parent.GroupInterval
+	}
+	if resolved.RepeatInterval == nil {
+		resolved.RepeatInterval = parent.RepeatInterval
+	}
+	if len(resolved.MuteTimeIntervals) == 0 {
+		resolved.MuteTimeIntervals = parent.MuteTimeIntervals
+	}
+	if len(resolved.ActiveTimeIntervals) == 0 {
+		resolved.ActiveTimeIntervals = parent.ActiveTimeIntervals
+	}
+	return resolved
+}
+
 // ToLabels converts the NotificationSettings into data.Labels. When added to an AlertRule these labels ensure it will
-// match an autogenerated route with the correct settings.
+// match an autogenerated route with the correct settings. If parent is non-nil, the labels are computed against the
+// settings resolved against parent so that rules with equivalent effective policies produce identical labels.
 // Labels returned:
 //   - AutogeneratedRouteLabel: "true"
 //   - AutogeneratedRouteReceiverNameLabel: Receiver
-//   - AutogeneratedRouteSettingsHashLabel: Fingerprint (if the NotificationSettings are not all default)
-func (s *NotificationSettings) ToLabels() data.Labels {
+//   - AutogeneratedRouteSettingsHashLabel: Fingerprint of the resolved settings (if they are not all default)
+func (s *NotificationSettings) ToLabels(parent *NotificationSettings) data.Labels {
+	resolved := s.Resolve(parent)
 	result := make(data.Labels, 3)
 	result[AutogeneratedRouteLabel] = "true"
-	result[AutogeneratedRouteReceiverNameLabel] = s.Receiver
-	if !s.IsAllDefault() {
-		result[AutogeneratedRouteSettingsHashLabel] = s.Fingerprint().String()
+	result[AutogeneratedRouteReceiverNameLabel] = resolved.Receiver
+	if !s.IsAllDefault(parent) {
+		result[AutogeneratedRouteSettingsHashLabel] = resolved.Fingerprint().String()
 	}
 	return result
 }
@@ -136,14 +190,24 @@ func (s *NotificationSettings) Equals(other *NotificationSettings) bool {
 	if !slices.Equal(s.MuteTimeIntervals, other.MuteTimeIntervals) {
 		return false
 	}
+	if !slices.Equal(s.ActiveTimeIntervals, other.ActiveTimeIntervals) {
+		return false
+	}
 	sGr := s.GroupBy
 	oGr := other.GroupBy
 	return slices.Equal(sGr, oGr)
 }
 
-// IsAllDefault checks if the NotificationSettings object has all default values for optional fields (all except Receiver) .
-func (s *NotificationSettings) IsAllDefault() bool {
-	return len(s.GroupBy) == 0 && s.GroupWait == nil && s.GroupInterval == nil && s.RepeatInterval == nil && len(s.MuteTimeIntervals) == 0
+// IsAllDefault checks if the NotificationSettings object has all default values, relative to parent. If parent is
+// nil, its optional fields (all except Receiver) are compared against the zero value, as for a root policy.
+// Otherwise, s is considered default when it resolves to settings indistinguishable from parent itself, Receiver
+// included, i.e. it introduces no effective override.
+func (s *NotificationSettings) IsAllDefault(parent *NotificationSettings) bool {
+	if parent == nil {
+		return len(s.GroupBy) == 0 && s.GroupWait == nil && s.GroupInterval == nil && s.RepeatInterval == nil &&
+			len(s.MuteTimeIntervals) == 0 && len(s.ActiveTimeIntervals) == 0
+	}
+	return s.Resolve(parent).Fingerprint() == parent.Fingerprint()
 }
 
 // NewDefaultNotificationSettings creates a new default NotificationSettings with the specified receiver.
@@ -186,5 +250,8 @@ func (s *NotificationSettings) Fingerprint() data.Fingerprint {
 	for _, interval := range s.MuteTimeIntervals {
 		writeString(interval)
 	}
+	for _, interval := range s.ActiveTimeIntervals {
+		writeString(interval)
+	}
 	return data.Fingerprint(h.Sum64())
 }
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func durationPointer(d model.Duration) *model.Duration {
+	return &d
+}
+
+func TestNotificationSettings_Resolve(t *testing.T) {
+	t.Run("nil parent returns s unchanged", func(t *testing.T) {
+		s := NotificationSettings{Receiver: "receiver"}
+		require.Equal(t, s, s.Resolve(nil))
+	})
+
+	t.Run("unset fields are inherited from parent", func(t *testing.T) {
+		parent := &NotificationSettings{
+			Receiver:          "parent-receiver",
+			GroupBy:           []string{"alertname"},
+			GroupWait:         durationPointer(model.Duration(10)),
+			GroupInterval:     durationPointer(model.Duration(20)),
+			RepeatInterval:    durationPointer(model.Duration(30)),
+			MuteTimeIntervals: []string{"weekends"},
+		}
+		s := NotificationSettings{}
+
+		resolved := s.Resolve(parent)
+
+		require.Equal(t, *parent, resolved)
+	})
+
+	t.Run("fields set on s take precedence over parent", func(t *testing.T) {
+		parent := &NotificationSettings{
+			Receiver:       "parent-receiver",
+			GroupWait:      durationPointer(model.Duration(10)),
+			RepeatInterval: durationPointer(model.Duration(30)),
+		}
+		s := NotificationSettings{
+			Receiver:  "own-receiver",
+			GroupWait: durationPointer(model.Duration(99)),
+		}
+
+		resolved := s.Resolve(parent)
+
+		require.Equal(t, "own-receiver", resolved.Receiver)
+		require.Equal(t, durationPointer(model.Duration(99)), resolved.GroupWait)
+		require.Equal(t, parent.RepeatInterval, resolved.RepeatInterval)
+	})
+}
+
+func TestNotificationSettings_IsAllDefault(t *testing.T) {
+	t.Run("nil parent compares against the zero value", func(t *testing.T) {
+		require.True(t, (&NotificationSettings{Receiver: "receiver"}).IsAllDefault(nil))
+		require.False(t, (&NotificationSettings{Receiver: "receiver", GroupWait: durationPointer(model.Duration(10))}).IsAllDefault(nil))
+	})
+
+	t.Run("empty receiver with nil parent is all default", func(t *testing.T) {
+		require.True(t, (&NotificationSettings{}).IsAllDefault(nil))
+	})
+
+	t.Run("empty receiver with parent resolves to parent and is all default", func(t *testing.T) {
+		parent := &NotificationSettings{Receiver: "parent-receiver", GroupWait: durationPointer(model.Duration(10))}
+		s := &NotificationSettings{}
+
+		require.True(t, s.IsAllDefault(parent))
+	})
+
+	t.Run("explicit override equal to parent is all default", func(t *testing.T) {
+		parent := &NotificationSettings{
+			Receiver:  "parent-receiver",
+			GroupWait: durationPointer(model.Duration(10)),
+		}
+		s := &NotificationSettings{
+			Receiver:  "parent-receiver",
+			GroupWait: durationPointer(model.Duration(10)),
+		}
+
+		require.True(t, s.IsAllDefault(parent))
+	})
+
+	t.Run("override that differs from parent is not all default", func(t *testing.T) {
+		parent := &NotificationSettings{
+			Receiver:  "parent-receiver",
+			GroupWait: durationPointer(model.Duration(10)),
+		}
+		s := &NotificationSettings{
+			Receiver:  "parent-receiver",
+			GroupWait: durationPointer(model.Duration(20)),
+		}
+
+		require.False(t, s.IsAllDefault(parent))
+	})
+}
+
+func TestNotificationSettings_ToLabels(t *testing.T) {
+	t.Run("all default settings omit the settings hash label", func(t *testing.T) {
+		s := &NotificationSettings{Receiver: "receiver"}
+
+		labels := s.ToLabels(nil)
+
+		require.Equal(t, "receiver", labels[AutogeneratedRouteReceiverNameLabel])
+		require.NotContains(t, labels, AutogeneratedRouteSettingsHashLabel)
+	})
+
+	t.Run("non-default settings include the settings hash label", func(t *testing.T) {
+		s := &NotificationSettings{Receiver: "receiver", GroupWait: durationPointer(model.Duration(10))}
+
+		labels := s.ToLabels(nil)
+
+		require.Contains(t, labels, AutogeneratedRouteSettingsHashLabel)
+	})
+
+	t.Run("receiver inherited from parent is reflected in the labels", func(t *testing.T) {
+		parent := &NotificationSettings{Receiver: "parent-receiver"}
+		s := &NotificationSettings{}
+
+		labels := s.ToLabels(parent)
+
+		require.Equal(t, "parent-receiver", labels[AutogeneratedRouteReceiverNameLabel])
+	})
+}
+
+func TestNotificationSettings_Validate(t *testing.T) {
+	t.Run("receiver required unless inherited from parent", func(t *testing.T) {
+		s := NotificationSettings{}
+		require.Error(t, s.Validate(nil, nil))
+		require.NoError(t, s.Validate(&NotificationSettings{Receiver: "parent-receiver"}, nil))
+	})
+
+	t.Run("durations must be positive", func(t *testing.T) {
+		s := NotificationSettings{Receiver: "receiver", GroupInterval: durationPointer(model.Duration(0))}
+		require.Error(t, s.Validate(nil, nil))
+	})
+
+	t.Run("active time intervals must exist in availableTimeIntervals", func(t *testing.T) {
+		s := NotificationSettings{Receiver: "receiver", ActiveTimeIntervals: []string{"business-hours"}}
+		require.Error(t, s.Validate(nil, nil))
+		require.Error(t, s.Validate(nil, []string{"weekends"}))
+		require.NoError(t, s.Validate(nil, []string{"business-hours", "weekends"}))
+	})
+}
+
+func TestNotificationSettings_ActiveTimeIntervals_Resolve(t *testing.T) {
+	t.Run("empty ActiveTimeIntervals is inherited from parent", func(t *testing.T) {
+		parent := &NotificationSettings{Receiver: "parent-receiver", ActiveTimeIntervals: []string{"business-hours"}}
+		s := NotificationSettings{}
+
+		resolved := s.Resolve(parent)
+
+		require.Equal(t, parent.ActiveTimeIntervals, resolved.ActiveTimeIntervals)
+	})
+
+	t.Run("explicit ActiveTimeIntervals overrides parent", func(t *testing.T) {
+		parent := &NotificationSettings{Receiver: "parent-receiver", ActiveTimeIntervals: []string{"business-hours"}}
+		s := NotificationSettings{ActiveTimeIntervals: []string{"weekends"}}
+
+		resolved := s.Resolve(parent)
+
+		require.Equal(t, []string{"weekends"}, resolved.ActiveTimeIntervals)
+	})
+
+	t.Run("same ActiveTimeIntervals as parent is all default", func(t *testing.T) {
+		parent := &NotificationSettings{Receiver: "parent-receiver", ActiveTimeIntervals: []string{"business-hours"}}
+		s := &NotificationSettings{Receiver: "parent-receiver", ActiveTimeIntervals: []string{"business-hours"}}
+
+		require.True(t, s.IsAllDefault(parent))
+	})
+
+	t.Run("different ActiveTimeIntervals than parent is not all default", func(t *testing.T) {
+		parent := &NotificationSettings{Receiver: "parent-receiver", ActiveTimeIntervals: []string{"business-hours"}}
+		s := &NotificationSettings{Receiver: "parent-receiver", ActiveTimeIntervals: []string{"weekends"}}
+
+		require.False(t, s.IsAllDefault(parent))
+	})
+}
@@ -0,0 +1,310 @@
+package rendering
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	renderInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "rendering",
+		Name:      "in_flight",
+		Help:      "Number of render requests currently in flight against a renderer endpoint.",
+	}, []string{"endpoint"})
+	renderFailoversTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "rendering",
+		Name:      "failovers_total",
+		Help:      "Number of times a render request failed over away from a renderer endpoint.",
+	}, []string{"endpoint"})
+)
+
+// renderStrategyName identifies one of the pluggable strategies used to pick a renderer endpoint.
+type renderStrategyName string
+
+const (
+	// StrategyRoundRobin cycles through the healthy endpoints in order.
+	StrategyRoundRobin renderStrategyName = "round-robin"
+	// StrategyLeastInFlight sends the request to the healthy endpoint with the fewest in-flight requests.
+	StrategyLeastInFlight renderStrategyName = "least-in-flight"
+	// StrategyConsistentHash routes requests with the same key (typically a dashboard UID) to the same
+	// endpoint, so repeated renders of the same dashboard hit a warm cache on the renderer side.
+	StrategyConsistentHash renderStrategyName = "consistent-hash"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	minBackoff                 = 5 * time.Second
+	maxBackoff                 = 5 * time.Minute
+	defaultFailoverBudget      = 2
+)
+
+// endpointState tracks the health and load of a single remote renderer endpoint.
+type endpointState struct {
+	url string
+
+	healthy  atomic.Bool
+	inFlight atomic.Int64
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	backoff             time.Duration
+	retryAt             time.Time
+}
+
+func newEndpointState(url string) *endpointState {
+	e := &endpointState{url: url, backoff: minBackoff}
+	e.healthy.Store(true)
+	return e
+}
+
+func (e *endpointState) isAvailable(now time.Time) bool {
+	if e.healthy.Load() {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.retryAt)
+}
+
+func (e *endpointState) markHealthy() {
+	e.healthy.Store(true)
+	e.mu.Lock()
+	e.consecutiveFailures = 0
+	e.backoff = minBackoff
+	e.mu.Unlock()
+}
+
+func (e *endpointState) failureCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.consecutiveFailures
+}
+
+func (e *endpointState) markUnhealthy(now time.Time) {
+	e.mu.Lock()
+	e.healthy.Store(false)
+	e.consecutiveFailures++
+	e.retryAt = now.Add(e.backoff)
+	e.backoff *= 2
+	if e.backoff > maxBackoff {
+		e.backoff = maxBackoff
+	}
+	e.mu.Unlock()
+}
+
+// rendererPool load-balances and fails over render and version requests across a set of remote
+// renderer endpoints, keeping track of which endpoints are currently healthy.
+type rendererPool struct {
+	log       log.Logger
+	strategy  renderStrategyName
+	endpoints []*endpointState
+
+	healthCheckInterval time.Duration
+	failoverBudget      int
+
+	rrCounter atomic.Uint64
+}
+
+// newRendererPool builds a pool for urls using the given strategy. failoverBudget and healthCheckInterval
+// configure RenderWithFailover's retry budget and the run loop's probe cadence respectively; a value <= 0
+// falls back to the package default, so callers that don't have (or don't set) a config value still get
+// sane behavior.
+func newRendererPool(urls []string, strategy renderStrategyName, failoverBudget int, healthCheckInterval time.Duration, logger log.Logger) *rendererPool {
+	endpoints := make([]*endpointState, 0, len(urls))
+	for _, u := range urls {
+		endpoints = append(endpoints, newEndpointState(u))
+	}
+	if failoverBudget <= 0 {
+		failoverBudget = defaultFailoverBudget
+	}
+	if healthCheckInterval <= 0 {
+		healthCheckInterval = defaultHealthCheckInterval
+	}
+	return &rendererPool{
+		log:                 logger,
+		strategy:            strategy,
+		endpoints:           endpoints,
+		healthCheckInterval: healthCheckInterval,
+		failoverBudget:      failoverBudget,
+	}
+}
+
+func strategyFromConfig(cfg *setting.Cfg) renderStrategyName {
+	switch renderStrategyName(strings.TrimSpace(cfg.RendererStrategy)) {
+	case StrategyLeastInFlight:
+		return StrategyLeastInFlight
+	case StrategyConsistentHash:
+		return StrategyConsistentHash
+	default:
+		return StrategyRoundRobin
+	}
+}
+
+func splitRendererEndpoints(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var endpoints []string
+	for _, e := range strings.Split(raw, ",") {
+		e = strings.TrimSpace(e)
+		if e != "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// next picks a healthy endpoint according to the pool's configured strategy. key is used by the
+// consistent-hash strategy (e.g. a dashboard UID) and ignored by the others. It returns "" when the
+// pool has no endpoints configured at all, so callers can fall back to Cfg.RendererUrl directly.
+func (p *rendererPool) next(key string) string {
+	if p == nil || len(p.endpoints) == 0 {
+		return ""
+	}
+
+	now := time.Now()
+	available := make([]*endpointState, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		if e.isAvailable(now) {
+			available = append(available, e)
+		}
+	}
+	// If every endpoint is marked unhealthy, degrade to trying them all rather than failing outright.
+	if len(available) == 0 {
+		available = p.endpoints
+	}
+
+	var chosen *endpointState
+	switch p.strategy {
+	case StrategyLeastInFlight:
+		for _, e := range available {
+			if chosen == nil || e.inFlight.Load() < chosen.inFlight.Load() {
+				chosen = e
+			}
+		}
+	case StrategyConsistentHash:
+		chosen = rendezvousPick(available, key)
+	default: // StrategyRoundRobin
+		idx := p.rrCounter.Add(1)
+		chosen = available[idx%uint64(len(available))]
+	}
+
+	return chosen.url
+}
+
+// rendezvousPick implements rendezvous (highest random weight) hashing: it scores every candidate
+// endpoint independently against key and returns the highest-scoring one. Because an endpoint's score
+// for a given key never depends on which other endpoints are present, removing or restoring endpoints
+// from the candidate set only remaps the keys that were routed to the endpoint that changed, unlike
+// hash % len(candidates), where every key's target shifts whenever the candidate count changes.
+func rendezvousPick(candidates []*endpointState, key string) *endpointState {
+	var best *endpointState
+	var bestScore uint64
+	for _, e := range candidates {
+		score := rendezvousScore(e.url, key)
+		if best == nil || score > bestScore {
+			best = e
+			bestScore = score
+		}
+	}
+	return best
+}
+
+func rendezvousScore(endpoint, key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(endpoint))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// RenderWithFailover calls fn with a chosen endpoint, retrying against the next healthy endpoint on
+// error (timeout, 5xx, connection refused) up to the pool's failover budget.
+func (p *rendererPool) RenderWithFailover(ctx context.Context, key string, fn func(ctx context.Context, endpoint string) error) error {
+	if p == nil || len(p.endpoints) == 0 {
+		return fmt.Errorf("no renderer endpoints configured")
+	}
+
+	endpoint := p.next(key)
+	var lastErr error
+	tried := map[string]bool{}
+
+	for attempt := 0; attempt <= p.failoverBudget; attempt++ {
+		if endpoint == "" || tried[endpoint] {
+			break
+		}
+		tried[endpoint] = true
+
+		state := p.stateFor(endpoint)
+		state.inFlight.Add(1)
+		renderInFlight.WithLabelValues(endpoint).Set(float64(state.inFlight.Load()))
+		err := fn(ctx, endpoint)
+		state.inFlight.Add(-1)
+		renderInFlight.WithLabelValues(endpoint).Set(float64(state.inFlight.Load()))
+
+		if err == nil {
+			state.markHealthy()
+			return nil
+		}
+
+		lastErr = err
+		state.markUnhealthy(time.Now())
+		renderFailoversTotal.WithLabelValues(endpoint).Inc()
+		endpoint = p.next(key)
+	}
+
+	return fmt.Errorf("all renderer endpoints failed: %w", lastErr)
+}
+
+func (p *rendererPool) stateFor(url string) *endpointState {
+	for _, e := range p.endpoints {
+		if e.url == url {
+			return e
+		}
+	}
+	return newEndpointState(url)
+}
+
+// run periodically probes every configured endpoint's version endpoint, reusing the same
+// 404-means-old-version logic as getRemotePluginVersion, and marks endpoints healthy/unhealthy
+// accordingly. It blocks until ctx is done.
+func (p *rendererPool) run(ctx context.Context, checkVersion func(ctx context.Context, endpoint string) (string, error)) {
+	if len(p.endpoints) == 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(p.healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, e := range p.endpoints {
+				// Each probe is independently bounded by checkVersion's own timeout, so one wedged
+				// endpoint can't stall the health check of the others in this loop.
+				if _, err := checkVersion(ctx, e.url); err != nil {
+					e.markUnhealthy(time.Now())
+					p.log.Warn("Renderer endpoint failed health check", "endpoint", e.url, "consecutiveFailures", e.failureCount(), "err", err)
+					continue
+				}
+				e.markHealthy()
+			}
+		}
+	}
+}
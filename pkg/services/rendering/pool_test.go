@@ -0,0 +1,100 @@
+package rendering
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRendererEndpoints(t *testing.T) {
+	require.Nil(t, splitRendererEndpoints(""))
+	require.Equal(t, []string{"http://a:8081/render"}, splitRendererEndpoints("http://a:8081/render"))
+	require.Equal(t, []string{"http://a:8081/render", "http://b:8081/render"}, splitRendererEndpoints("http://a:8081/render, http://b:8081/render"))
+}
+
+func TestNewRendererPoolAppliesConfiguredBudgetAndInterval(t *testing.T) {
+	pool := newRendererPool([]string{"http://a"}, StrategyRoundRobin, 5, 30*time.Second, log.New("rendering-test"))
+	require.Equal(t, 5, pool.failoverBudget)
+	require.Equal(t, 30*time.Second, pool.healthCheckInterval)
+}
+
+func TestNewRendererPoolFallsBackToDefaultsWhenUnconfigured(t *testing.T) {
+	pool := newRendererPool([]string{"http://a"}, StrategyRoundRobin, 0, 0, log.New("rendering-test"))
+	require.Equal(t, defaultFailoverBudget, pool.failoverBudget)
+	require.Equal(t, defaultHealthCheckInterval, pool.healthCheckInterval)
+}
+
+func TestRendererPoolRoundRobin(t *testing.T) {
+	pool := newRendererPool([]string{"http://a", "http://b"}, StrategyRoundRobin, 0, 0, log.New("rendering-test"))
+
+	first := pool.next("")
+	second := pool.next("")
+	require.NotEqual(t, first, second)
+	require.Equal(t, first, pool.next(""))
+}
+
+func TestRendererPoolSkipsUnhealthyEndpoints(t *testing.T) {
+	pool := newRendererPool([]string{"http://a", "http://b"}, StrategyRoundRobin, 0, 0, log.New("rendering-test"))
+	pool.stateFor("http://a").markUnhealthy(time.Now())
+
+	for i := 0; i < 4; i++ {
+		require.Equal(t, "http://b", pool.next(""))
+	}
+}
+
+func TestRendererPoolRenderWithFailover(t *testing.T) {
+	pool := newRendererPool([]string{"http://a", "http://b"}, StrategyRoundRobin, 0, 0, log.New("rendering-test"))
+
+	var attempted []string
+	err := pool.RenderWithFailover(context.Background(), "", func(ctx context.Context, endpoint string) error {
+		attempted = append(attempted, endpoint)
+		if len(attempted) == 1 {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, attempted, 2)
+	require.NotEqual(t, attempted[0], attempted[1])
+
+	// The endpoint that failed should have been marked unhealthy, so a subsequent pick skips it.
+	require.False(t, pool.stateFor(attempted[0]).healthy.Load())
+}
+
+func TestRendererPoolRenderWithFailoverAllFail(t *testing.T) {
+	pool := newRendererPool([]string{"http://a", "http://b"}, StrategyRoundRobin, 0, 0, log.New("rendering-test"))
+
+	attempts := 0
+	err := pool.RenderWithFailover(context.Background(), "", func(ctx context.Context, endpoint string) error {
+		attempts++
+		return fmt.Errorf("timeout")
+	})
+	require.Error(t, err)
+	require.Equal(t, 2, attempts)
+}
+
+func TestRendererPoolConsistentHashStableOnFailover(t *testing.T) {
+	pool := newRendererPool([]string{"http://a", "http://b", "http://c"}, StrategyConsistentHash, 0, 0, log.New("rendering-test"))
+
+	keys := []string{"dash-1", "dash-2", "dash-3", "dash-4", "dash-5"}
+	before := make(map[string]string, len(keys))
+	for _, k := range keys {
+		before[k] = pool.next(k)
+	}
+
+	downed := before[keys[0]]
+	pool.stateFor(downed).markUnhealthy(time.Now())
+
+	for _, k := range keys {
+		after := pool.next(k)
+		if before[k] == downed {
+			require.NotEqual(t, downed, after, "keys routed to the downed endpoint must move")
+		} else {
+			require.Equal(t, before[k], after, "keys not routed to the downed endpoint must not remap")
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package rendering
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// remoteCallTimeout bounds a single attempt against one renderer endpoint, whether a version probe or
+// a render request, so a wedged endpoint fails fast enough for the pool to fail over to another one
+// instead of hanging the caller (or, for health checks, every other endpoint queued behind it).
+const remoteCallTimeout = 10 * time.Second
+
+// RenderingService renders dashboards and panels to images by delegating to one or more remote
+// image-renderer plugin instances. Cfg.RendererUrl may hold a single endpoint or a comma-separated
+// list; when more than one is configured, requests are load-balanced and failed over across the pool.
+type RenderingService struct {
+	Cfg    *setting.Cfg
+	log    log.Logger
+	domain string
+
+	pool *rendererPool
+}
+
+// ProvideService builds a RenderingService and the renderer pool backing it.
+func ProvideService(cfg *setting.Cfg) (*RenderingService, error) {
+	rs := &RenderingService{
+		Cfg: cfg,
+		log: log.New("rendering"),
+	}
+	rs.pool = newRendererPool(splitRendererEndpoints(cfg.RendererUrl), strategyFromConfig(cfg), cfg.RendererFailoverBudget, cfg.RendererHealthCheckInterval, rs.log)
+	return rs, nil
+}
+
+// Run starts the background health-check loop for the renderer pool. It blocks until ctx is done.
+func (rs *RenderingService) Run(ctx context.Context) error {
+	if rs.pool == nil {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	rs.pool.run(ctx, rs.getRemotePluginVersionFor)
+	return ctx.Err()
+}
+
+// pickEndpoint selects a remote renderer endpoint from the pool, passing key to the pool's strategy
+// (only the consistent-hash strategy uses it). It falls back to the first configured endpoint when the pool
+// has not been initialized, which keeps getURL/getRemotePluginVersion usable in isolation, e.g. in tests.
+func (rs *RenderingService) pickEndpoint(key string) string {
+	if rs.pool != nil {
+		if endpoint := rs.pool.next(key); endpoint != "" {
+			return endpoint
+		}
+	}
+	endpoints := splitRendererEndpoints(rs.Cfg.RendererUrl)
+	if len(endpoints) == 0 {
+		return ""
+	}
+	return endpoints[0]
+}
+
+func (rs *RenderingService) getURL(path string) string {
+	if endpoint := rs.pickEndpoint(path); endpoint != "" {
+		// The backend rendering service can potentially be remote, so the RendererCallbackUrl is used
+		// for the image rendering service to know what URL to call back into Grafana on.
+		callbackUrl := rs.Cfg.RendererCallbackUrl
+		return callbackUrl + path + "&render=1"
+	}
+
+	protocol := rs.Cfg.Protocol
+	switch protocol {
+	case setting.HTTPScheme:
+		protocol = "http"
+	case setting.HTTPSScheme, setting.HTTP2Scheme:
+		protocol = "https"
+	}
+
+	subPath := ""
+	if rs.Cfg.ServeFromSubPath {
+		subPath = rs.Cfg.AppSubURL
+	}
+
+	return fmt.Sprintf("%s://%s:%s%s/%s&render=1", protocol, rs.domain, rs.Cfg.HTTPPort, subPath, path)
+}
+
+// getRemotePluginVersion queries the version endpoint of a healthy remote renderer, retrying against
+// the next healthy endpoint on failure up to the pool's failover budget. Renderer plugins older than
+// the version endpoint respond with a 404, which is treated as a valid but old version rather than an
+// error. When the pool has no endpoints configured at all, it falls back to a direct check against
+// Cfg.RendererUrl, which keeps this usable in isolation, e.g. in tests.
+func (rs *RenderingService) getRemotePluginVersion() (string, error) {
+	if rs.pool != nil && len(rs.pool.endpoints) > 0 {
+		var version string
+		err := rs.pool.RenderWithFailover(context.Background(), "", func(ctx context.Context, endpoint string) error {
+			v, err := rs.getRemotePluginVersionFor(ctx, endpoint)
+			if err != nil {
+				return err
+			}
+			version = v
+			return nil
+		})
+		return version, err
+	}
+
+	endpoint := rs.Cfg.RendererUrl
+	if endpoints := splitRendererEndpoints(endpoint); len(endpoints) > 0 {
+		endpoint = endpoints[0]
+	}
+	return rs.getRemotePluginVersionFor(context.Background(), endpoint)
+}
+
+// getRemotePluginVersionFor queries the version endpoint of a specific renderer endpoint, bounded by
+// remoteCallTimeout. It is also used by the pool's background health-check loop to probe every
+// configured endpoint independently, and by getRemotePluginVersion as the per-attempt call inside
+// RenderWithFailover.
+func (rs *RenderingService) getRemotePluginVersionFor(ctx context.Context, endpoint string) (string, error) {
+	reqURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	reqURL.Path = path.Join(reqURL.Path, "version")
+
+	ctx, cancel := context.WithTimeout(ctx, remoteCallTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			rs.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	// Older versions of the renderer plugin do not expose this endpoint; assume a valid but old version.
+	if resp.StatusCode == http.StatusNotFound {
+		return "1.0.0", nil
+	}
+
+	var info struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", err
+	}
+
+	return info.Version, nil
+}
+
+// RenderOpts describes a single render request.
+type RenderOpts struct {
+	// Path is the Grafana path to render, e.g. "render/d-solo/<uid>/<slug>?...".
+	Path string
+}
+
+// Render renders Path by calling out to a remote renderer, picking an endpoint from the pool and
+// transparently retrying against the next healthy endpoint on failure (timeout, 5xx, connection
+// refused) up to the pool's failover budget. The dashboard path, without its query string, is used as
+// the failover key, so a consistent-hash pool keeps repeated renders of the same dashboard on the same
+// renderer.
+func (rs *RenderingService) Render(ctx context.Context, opts RenderOpts) ([]byte, error) {
+	if rs.pool == nil || len(rs.pool.endpoints) == 0 {
+		return nil, fmt.Errorf("no renderer endpoints configured")
+	}
+
+	key := opts.Path
+	if u, err := url.Parse(opts.Path); err == nil {
+		key = u.Path
+	}
+
+	var body []byte
+	err := rs.pool.RenderWithFailover(ctx, key, func(ctx context.Context, endpoint string) error {
+		reqCtx, cancel := context.WithTimeout(ctx, remoteCallTimeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, renderURL(endpoint, opts.Path), nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				rs.log.Warn("Failed to close response body", "err", err)
+			}
+		}()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("renderer %s responded with status %d", endpoint, resp.StatusCode)
+		}
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		body = b
+		return nil
+	})
+	return body, err
+}
+
+func renderURL(endpoint, path string) string {
+	return strings.TrimRight(endpoint, "/") + "/" + strings.TrimLeft(path, "/")
+}
@@ -17,6 +17,50 @@ var (
 	batchSize = 500
 )
 
+// roleAdminPrefix identifies a managed role that grants its holder admin authority over a bounded
+// set of roles they own, rather than full org-admin authority over every user in the org. The role
+// name carries both the UID of the role being administered and the user it is granted to, following
+// the same "managed:<kind>:<id>:..." convention as the other managed role prefixes below:
+//
+//	managed:roleadmins:<roleUID>:<adminUserID>:permissions
+const roleAdminPrefix = "managed:roleadmins:"
+
+// RoleAdmin represents a row of the role_admin table, granting adminUserID the authority to manage
+// assignments of roleID.
+type RoleAdmin struct {
+	RoleID      int64     `xorm:"role_id"`
+	AdminUserID int64     `xorm:"admin_user_id"`
+	Created     time.Time `xorm:"created"`
+}
+
+// parseRoleAdminScopeOwner extracts the UID of the role that a "managed:roleadmins:<roleUID>:<adminUserID>:permissions"
+// role is scoped to, so that assignments performed by its holder can later be authorized against that scope.
+// It returns ok=false for any role name that does not use the role-admin prefix.
+func parseRoleAdminScopeOwner(name string) (roleUID string, ok bool) {
+	if !strings.HasPrefix(name, roleAdminPrefix) {
+		return "", false
+	}
+	parts := strings.Split(name, ":")
+	if len(parts) != 5 {
+		return "", false
+	}
+	return parts[2], true
+}
+
+// parseRoleAdminAssignee extracts the UID of the role being administered and the ID of the admin user
+// it is granted to from a "managed:roleadmins:<roleUID>:<adminUserID>:permissions" role name.
+func parseRoleAdminAssignee(name string) (roleUID string, adminUserID int64, err error) {
+	parts := strings.Split(name, ":")
+	if len(parts) != 5 {
+		return "", 0, fmt.Errorf("invalid role admin managed role name %q", name)
+	}
+	adminUserID, err = strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid role admin managed role name %q: %w", name, err)
+	}
+	return parts[2], adminUserID, nil
+}
+
 type permissionMigrator struct {
 	sess    *xorm.Session
 	dialect migrator.Dialect
@@ -34,6 +78,12 @@ func (m *permissionMigrator) findRole(orgID int64, name string) (accesscontrol.R
 	return role, err
 }
 
+func (m *permissionMigrator) findRoleByUID(orgID int64, uid string) (accesscontrol.Role, bool, error) {
+	var role accesscontrol.Role
+	found, err := m.sess.Table("role").Where("org_id = ? AND uid = ?", orgID, uid).Get(&role)
+	return role, found, err
+}
+
 func (m *permissionMigrator) bulkCreateRoles(allRoles []*accesscontrol.Role) ([]*accesscontrol.Role, error) {
 	if len(allRoles) == 0 {
 		return nil, nil
@@ -69,6 +119,7 @@ func (m *permissionMigrator) bulkAssignRoles(rolesMap map[int64]map[string]*acce
 	userRoleAssignments := make([]accesscontrol.UserRole, 0)
 	teamRoleAssignments := make([]accesscontrol.TeamRole, 0)
 	builtInRoleAssignments := make([]accesscontrol.BuiltinRole, 0)
+	roleAdminAssignments := make([]RoleAdmin, 0)
 
 	for orgID, roleNames := range assignments {
 		for name := range roleNames {
@@ -77,7 +128,30 @@ func (m *permissionMigrator) bulkAssignRoles(rolesMap map[int64]map[string]*acce
 				return &ErrUnknownRole{name}
 			}
 
-			if strings.HasPrefix(name, "managed:users") {
+			if strings.HasPrefix(name, roleAdminPrefix) {
+				roleUID, adminUserID, err := parseRoleAdminAssignee(name)
+				if err != nil {
+					return err
+				}
+				administeredRole, found, err := m.findRoleByUID(orgID, roleUID)
+				if err != nil {
+					return err
+				}
+				if !found {
+					return &ErrUnknownRole{name}
+				}
+				userRoleAssignments = append(userRoleAssignments, accesscontrol.UserRole{
+					OrgID:   role.OrgID,
+					RoleID:  role.ID,
+					UserID:  adminUserID,
+					Created: ts,
+				})
+				roleAdminAssignments = append(roleAdminAssignments, RoleAdmin{
+					RoleID:      administeredRole.ID,
+					AdminUserID: adminUserID,
+					Created:     ts,
+				})
+			} else if strings.HasPrefix(name, "managed:users") {
 				userID, err := strconv.ParseInt(strings.Split(name, ":")[2], 10, 64)
 				if err != nil {
 					return err
@@ -128,10 +202,18 @@ func (m *permissionMigrator) bulkAssignRoles(rolesMap map[int64]map[string]*acce
 		return err
 	}
 
-	return batch(len(builtInRoleAssignments), batchSize, func(start, end int) error {
+	err = batch(len(builtInRoleAssignments), batchSize, func(start, end int) error {
 		_, err := m.sess.Table("builtin_role").InsertMulti(builtInRoleAssignments[start:end])
 		return err
 	})
+	if err != nil {
+		return err
+	}
+
+	return batch(len(roleAdminAssignments), batchSize, func(start, end int) error {
+		_, err := m.sess.Table("role_admin").InsertMulti(roleAdminAssignments[start:end])
+		return err
+	})
 }
 
 // createRoles creates a list of roles and returns their id, orgID, name in a single query
@@ -139,7 +221,7 @@ func (m *permissionMigrator) createRoles(roles []*accesscontrol.Role, start int,
 	ts := time.Now()
 	createdRoles := make([]*accesscontrol.Role, 0, len(roles))
 	valueStrings := make([]string, len(roles))
-	args := make([]interface{}, 0, len(roles)*5)
+	args := make([]interface{}, 0, len(roles)*6)
 
 	for i, r := range roles {
 		uid, err := generateNewRoleUID(m.sess, r.OrgID)
@@ -147,13 +229,18 @@ func (m *permissionMigrator) createRoles(roles []*accesscontrol.Role, start int,
 			return nil, err
 		}
 
-		valueStrings[i] = "(?, ?, ?, 1, ?, ?)"
-		args = append(args, r.OrgID, uid, r.Name, ts, ts)
+		var scopeOwnerRoleUID interface{}
+		if owner, ok := parseRoleAdminScopeOwner(r.Name); ok {
+			scopeOwnerRoleUID = owner
+		}
+
+		valueStrings[i] = "(?, ?, ?, 1, ?, ?, ?)"
+		args = append(args, r.OrgID, uid, r.Name, ts, ts, scopeOwnerRoleUID)
 	}
 
 	// Insert and fetch at once
 	valueString := strings.Join(valueStrings, ",")
-	sql := fmt.Sprintf("INSERT INTO role (org_id, uid, name, version, created, updated) VALUES %s RETURNING id, org_id, name", valueString)
+	sql := fmt.Sprintf("INSERT INTO role (org_id, uid, name, version, created, updated, scope_owner_role_uid) VALUES %s RETURNING id, org_id, name", valueString)
 	if errCreate := m.sess.SQL(sql, args...).Find(&createdRoles); errCreate != nil {
 		return nil, errCreate
 	}
@@ -188,6 +275,18 @@ func (m *permissionMigrator) createRolesMySQL(roles []*accesscontrol.Role, start
 		return nil, errCreate
 	}
 
+	// Stamp the scope a role-admin managed role is bound to, so assignments performed by its holder
+	// can later be authorized against that scope.
+	for _, r := range roles {
+		scopeOwnerRoleUID, ok := parseRoleAdminScopeOwner(r.Name)
+		if !ok {
+			continue
+		}
+		if _, err := m.sess.Exec("UPDATE role SET scope_owner_role_uid = ? WHERE org_id = ? AND uid = ?", scopeOwnerRoleUID, r.OrgID, r.UID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Fetch newly created roles
 	if errFindInsertions := m.sess.Table("role").
 		Where(strings.Join(where, " OR "), args...).
@@ -0,0 +1,51 @@
+package accesscontrol
+
+import (
+	"xorm.io/xorm"
+)
+
+// IsRoleAdminAuthorized reports whether adminUserID is authorized, via the role_admin table, to grant or
+// revoke assignments of the role identified by targetRoleUID in orgID. It is the enforcement primitive
+// backing the data seeded by seedRoleAdminsMigration: adminUserID is authorized for targetRoleUID if
+// either
+//   - targetRoleUID is itself one of the roles adminUserID administers (a row in role_admin), or
+//   - targetRoleUID was created under another role's authority (its scope_owner_role_uid, stamped in
+//     createRoles/createRolesMySQL) and adminUserID administers that owning role.
+//
+// Callers that grant or revoke a user/team/builtin role assignment must call this (in addition to, not
+// instead of, the existing org-admin check) before allowing a non-org-admin caller to perform the
+// assignment; this package only owns the storage and has no assignment-granting code path of its own to
+// call it from.
+func IsRoleAdminAuthorized(sess *xorm.Session, orgID, adminUserID int64, targetRoleUID string) (bool, error) {
+	type roleRow struct {
+		ID                int64  `xorm:"id"`
+		ScopeOwnerRoleUID string `xorm:"scope_owner_role_uid"`
+	}
+
+	var target roleRow
+	found, err := sess.Table("role").Where("org_id = ? AND uid = ?", orgID, targetRoleUID).Get(&target)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, nil
+	}
+
+	scopeRoleUID := targetRoleUID
+	if target.ScopeOwnerRoleUID != "" {
+		scopeRoleUID = target.ScopeOwnerRoleUID
+	}
+
+	scopeRole := target
+	if scopeRoleUID != targetRoleUID {
+		found, err = sess.Table("role").Where("org_id = ? AND uid = ?", orgID, scopeRoleUID).Get(&scopeRole)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	return sess.Table("role_admin").Where("role_id = ? AND admin_user_id = ?", scopeRole.ID, adminUserID).Exist()
+}
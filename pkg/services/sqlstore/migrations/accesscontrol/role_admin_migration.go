@@ -0,0 +1,97 @@
+package accesscontrol
+
+import (
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRoleAdminMigrations creates the storage needed for "role admins": org admins whose management
+// authority is bounded to a specific set of roles they own, rather than to every user in the org. This
+// only creates and backfills the storage (role_admin, role.scope_owner_role_uid); enforcing the bound at
+// assignment time is IsRoleAdminAuthorized's job, and is the caller's responsibility to invoke.
+func AddRoleAdminMigrations(mg *migrator.Migrator) {
+	roleAdminTable := migrator.Table{
+		Name: "role_admin",
+		Columns: []*migrator.Column{
+			{Name: "role_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "admin_user_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"role_id", "admin_user_id"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"admin_user_id"}},
+		},
+	}
+
+	mg.AddMigration("create role_admin table", migrator.NewAddTableMigration(roleAdminTable))
+	mg.AddMigration("add unique index role_admin.role_id_admin_user_id", migrator.NewAddIndexMigration(roleAdminTable, roleAdminTable.Indices[0]))
+	mg.AddMigration("add index role_admin.admin_user_id", migrator.NewAddIndexMigration(roleAdminTable, roleAdminTable.Indices[1]))
+
+	mg.AddMigration("add scope_owner_role_uid column to role table", migrator.NewAddColumnMigration(migrator.Table{Name: "role"}, &migrator.Column{
+		Name: "scope_owner_role_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: true,
+	}))
+
+	mg.AddMigration("seed role_admin from existing scoped org admins", &seedRoleAdminsMigration{})
+}
+
+// seedRoleAdminsMigration backfills the role_admin table for orgs that were already running with a
+// managed role scoped to a subset of role UIDs (i.e. a "managed:roleadmins:<roleUID>:<adminUserID>:permissions"
+// role assigned to a user) before role admins became a first-class concept.
+type seedRoleAdminsMigration struct {
+	migrator.MigrationBase
+}
+
+func (m *seedRoleAdminsMigration) SQL(migrator.Dialect) string {
+	return "code migration"
+}
+
+func (m *seedRoleAdminsMigration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
+	type scopedRole struct {
+		ID    int64  `xorm:"id"`
+		OrgID int64  `xorm:"org_id"`
+		Name  string `xorm:"name"`
+	}
+
+	var roles []scopedRole
+	if err := sess.Table("role").Where("name LIKE ?", roleAdminPrefix+"%").Find(&roles); err != nil {
+		return err
+	}
+
+	for _, r := range roles {
+		roleUID, adminUserID, err := parseRoleAdminAssignee(r.Name)
+		if err != nil {
+			// Skip managed roles that don't follow the role-admin naming convention; nothing to seed.
+			continue
+		}
+
+		// UIDs are only guaranteed unique within an org (see generateNewRoleUID), so the administered
+		// role lookup must be scoped to the same org as the managed:roleadmins role itself.
+		var administeredRole scopedRole
+		roleExists, err := sess.Table("role").Where("org_id = ? AND uid = ?", r.OrgID, roleUID).Get(&administeredRole)
+		if err != nil {
+			return err
+		}
+		if !roleExists {
+			continue
+		}
+
+		exists, err := sess.Table("user_role").Where("role_id = ? AND user_id = ?", r.ID, adminUserID).Exist()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			// The role was never actually assigned to the admin user; nothing to seed.
+			continue
+		}
+
+		if _, err := sess.Table("role_admin").Insert(&RoleAdmin{
+			RoleID:      administeredRole.ID,
+			AdminUserID: adminUserID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}